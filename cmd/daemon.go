@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jtepe/gopodgrab/pod"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonGlobalConcurrency int
+	daemonRetries           int
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Runs gopodgrab as a long-lived background archiver",
+	Long: `Daemon runs indefinitely, checking every managed podcast on
+its own cron schedule (a podcast's Schedule, hourly by default) and
+downloading any new episodes non-interactively, with no approval
+prompt.
+
+Send SIGINT or SIGTERM to shut down gracefully: gopodgrab stops
+scheduling new checks and waits for in-flight downloads to finish (or
+leaves them as resumable ".part" files) before exiting.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pods, err := pod.List()
+		if err != nil {
+			return err
+		}
+
+		opts := pod.DownloadOptions{
+			Concurrency:  pod.DefaultConcurrency,
+			RetryMax:     daemonRetries,
+			RetryBackoff: time.Second,
+		}
+
+		logger := slog.Default()
+		d := pod.NewDaemon(pods, opts, daemonGlobalConcurrency, logger)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := d.Start(); err != nil {
+			return err
+		}
+
+		logger.Info("daemon started", "podcasts", len(pods))
+
+		<-ctx.Done()
+		logger.Info("shutting down, waiting for in-flight downloads to finish")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		d.Stop(shutdownCtx)
+
+		return nil
+	},
+}
+
+func init() {
+	daemonCmd.Flags().IntVar(&daemonGlobalConcurrency, "global-concurrency", pod.DefaultConcurrency, "maximum number of podcasts downloading at once across the whole daemon")
+	daemonCmd.Flags().IntVar(&daemonRetries, "retries", 3, "number of times to retry a failed episode download")
+
+	rootCmd.AddCommand(daemonCmd)
+}
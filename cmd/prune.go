@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/jtepe/gopodgrab/pod"
+	"github.com/spf13/cobra"
+)
+
+var pruneDryRun bool
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune [<podcast>|all] [<podcast>...]",
+	Short: "Removes episodes beyond a podcast's retention policy",
+	Long: `Prune deletes stored episodes that exceed a podcast's
+retention policy, keeping only the latest Retention.KeepLatest
+episodes and removing any older than Retention.MaxAge.
+
+The special name "all" prunes all managed podcasts.`,
+
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pods := make([]*pod.Podcast, 0, len(args))
+
+		for _, arg := range args {
+			if arg == pod.ReservedPodName {
+				all, err := pod.List()
+				if err != nil {
+					return err
+				}
+
+				return prunePods(all)
+			}
+
+			p, err := pod.Get(arg)
+			if err != nil {
+				return err
+			}
+
+			pods = append(pods, p)
+		}
+
+		return prunePods(pods)
+	},
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "print what would be removed without deleting anything")
+
+	rootCmd.AddCommand(pruneCmd)
+}
+
+func prunePods(pods []*pod.Podcast) error {
+	for _, p := range pods {
+		removed, err := p.Prune(pruneDryRun)
+		if err != nil {
+			return err
+		}
+
+		printPruned(p.Name, removed)
+	}
+
+	return nil
+}
+
+func printPruned(name string, removed []string) {
+	if len(removed) == 0 {
+		return
+	}
+
+	verb := "Removed"
+	if pruneDryRun {
+		verb = "Would remove"
+	}
+
+	fmt.Printf("%s: %s %d episode(s)\n", name, verb, len(removed))
+	for _, f := range removed {
+		fmt.Println(" ", f)
+	}
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jtepe/gopodgrab/pod"
+	"github.com/jtepe/gopodgrab/pod/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr    string
+	serveBaseURL string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Re-publishes archived podcasts over HTTP",
+	Long: `Serve starts an HTTP server that re-publishes every managed
+podcast as a normalized RSS feed, with episodes served from local
+storage. Point a podcast client at <host>/<podcast>/feed.xml to keep
+consuming a show's archive long after the original feed drops old
+episodes.`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pods, err := pod.List()
+		if err != nil {
+			return err
+		}
+
+		baseURL := serveBaseURL
+		if baseURL == "" {
+			baseURL = "http://localhost" + serveAddr
+		}
+
+		srv := server.New(pods, baseURL)
+
+		fmt.Printf("Serving %d podcast(s) on %s\n", len(pods), serveAddr)
+
+		return http.ListenAndServe(serveAddr, srv.Handler())
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveBaseURL, "base-url", "", `public base URL used to build feed and episode links (defaults to "http://localhost<addr>")`)
+
+	rootCmd.AddCommand(serveCmd)
+}
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/jtepe/gopodgrab/pod"
+	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 )
 
@@ -41,10 +45,26 @@ The special name "all" updates all managed podcasts.`,
 	},
 }
 
+var (
+	updateConcurrency int
+	updateRetries     int
+	updatePrune       bool
+)
+
+func init() {
+	updateCmd.Flags().IntVar(&updateConcurrency, "concurrency", pod.DefaultConcurrency, "number of episodes to download in parallel")
+	updateCmd.Flags().IntVar(&updateRetries, "retries", 3, "number of times to retry a failed episode download")
+	updateCmd.Flags().BoolVar(&updatePrune, "prune", false, "apply each podcast's retention policy after downloading")
+}
+
 func updatePods(pods []*pod.Podcast) error {
 	newEps := make(map[*pod.Podcast][]*pod.Episode)
 
 	for _, p := range pods {
+		if err := p.RefreshFeed(); err != nil {
+			return err
+		}
+
 		eps, err := p.NewEpisodes()
 		if err != nil {
 			return err
@@ -80,11 +100,51 @@ func updatePods(pods []*pod.Podcast) error {
 
 	if waitApproval(msg) {
 		for p, eps := range newEps {
-			if err := p.DownloadEpisodes(eps); err != nil {
+			opts := pod.DownloadOptions{
+				Concurrency:  updateConcurrency,
+				RetryMax:     updateRetries,
+				RetryBackoff: time.Second,
+				Progress:     newProgressReporter(),
+			}
+
+			if err := p.DownloadEpisodes(context.Background(), eps, opts); err != nil {
 				return err
 			}
+
+			if updatePrune {
+				removed, err := p.Prune(false)
+				if err != nil {
+					return err
+				}
+
+				printPruned(p.Name, removed)
+			}
 		}
 	}
 
 	return nil
 }
+
+// newProgressReporter returns a pod.DownloadOptions.Progress callback
+// that renders one live progress bar per episode currently downloading.
+func newProgressReporter() func(ep *pod.Episode, downloaded, total int64) {
+	var mu sync.Mutex
+	bars := make(map[*pod.Episode]*progressbar.ProgressBar)
+
+	return func(ep *pod.Episode, downloaded, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		bar, ok := bars[ep]
+		if !ok {
+			bar = progressbar.DefaultBytes(total, ep.Title)
+			bars[ep] = bar
+		}
+
+		bar.Set64(downloaded)
+
+		if total >= 0 && downloaded >= total {
+			delete(bars, ep)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package pod
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultSchedule is the cron expression used by a podcast whose
+// Schedule is left empty.
+const DefaultSchedule = "@hourly"
+
+// Daemon runs NewEpisodes and DownloadEpisodes for a set of managed
+// podcasts on their own schedule, non-interactively, so that
+// gopodgrab can be left running as a set-and-forget archiver.
+type Daemon struct {
+	pods        []*Podcast
+	cron        *cron.Cron
+	concurrency chan struct{} // caps podcasts downloading at once, across the whole daemon
+	opts        DownloadOptions
+	logger      *slog.Logger
+
+	// workCtx governs in-flight ticks and downloads. It is
+	// deliberately not tied to whatever context the caller used to
+	// detect a shutdown signal: cancelling it the instant SIGINT/
+	// SIGTERM arrives would abort downloads mid-transfer instead of
+	// letting them finish. Stop only cancels it once its own grace
+	// period elapses.
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+// NewDaemon builds a Daemon for pods. opts is used for every
+// download. globalConcurrency caps the number of podcasts
+// downloading at the same time across the whole daemon (a podcast's
+// own DownloadOptions.Concurrency still caps parallel episodes within
+// it); it defaults to DefaultConcurrency if <= 0.
+func NewDaemon(pods []*Podcast, opts DownloadOptions, globalConcurrency int, logger *slog.Logger) *Daemon {
+	if globalConcurrency <= 0 {
+		globalConcurrency = DefaultConcurrency
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	workCtx, cancelWork := context.WithCancel(context.Background())
+
+	return &Daemon{
+		pods:        pods,
+		cron:        cron.New(),
+		concurrency: make(chan struct{}, globalConcurrency),
+		opts:        opts,
+		logger:      logger,
+		workCtx:     workCtx,
+		cancelWork:  cancelWork,
+	}
+}
+
+// Start schedules every podcast on its own Schedule (DefaultSchedule
+// if unset) and begins running ticks in the background. It returns an
+// error if a podcast's Schedule cannot be parsed as a cron expression.
+func (d *Daemon) Start() error {
+	for _, p := range d.pods {
+		p := p
+
+		schedule := p.Schedule
+		if schedule == "" {
+			schedule = DefaultSchedule
+		}
+
+		if _, err := d.cron.AddFunc(schedule, func() { d.tick(p) }); err != nil {
+			return fmt.Errorf("pod: invalid schedule %q for podcast %q: %w", schedule, p.Name, err)
+		}
+	}
+
+	d.cron.Start()
+
+	return nil
+}
+
+// Stop stops scheduling new ticks and waits for any in-flight tick to
+// finish, or for ctx to be done, whichever comes first. Only then -
+// not on the signal that triggered the shutdown - is the downloads'
+// own context cancelled, so a tick that is still running when Stop is
+// called gets the whole of ctx's grace period to finish normally
+// before its in-flight downloads are aborted. Episodes still in
+// progress when that happens are left as resumable ".part" files, to
+// be picked up by the next call to DownloadEpisodes.
+func (d *Daemon) Stop(ctx context.Context) {
+	cronDone := d.cron.Stop()
+
+	select {
+	case <-cronDone.Done():
+	case <-ctx.Done():
+	}
+
+	d.cancelWork()
+}
+
+// tick runs a single check-and-download pass for p, respecting the
+// daemon's global concurrency cap.
+func (d *Daemon) tick(p *Podcast) {
+	select {
+	case d.concurrency <- struct{}{}:
+	case <-d.workCtx.Done():
+		return
+	}
+	defer func() { <-d.concurrency }()
+
+	log := d.logger.With("podcast", p.Name)
+
+	if err := p.RefreshFeed(); err != nil {
+		log.Error("refreshing feed failed", "error", err)
+		return
+	}
+
+	eps, err := p.NewEpisodes()
+	if err != nil {
+		log.Error("checking for new episodes failed", "error", err)
+		return
+	}
+
+	if len(eps) == 0 {
+		log.Info("no new episodes")
+		return
+	}
+
+	log.Info("downloading new episodes", "count", len(eps))
+
+	if err := p.DownloadEpisodes(d.workCtx, eps, d.opts); err != nil {
+		log.Error("download failed", "error", err)
+	}
+
+	if p.Retention.KeepLatest > 0 || p.Retention.MaxAge > 0 {
+		removed, err := p.Prune(false)
+		if err != nil {
+			log.Error("prune failed", "error", err)
+			return
+		}
+
+		if len(removed) > 0 {
+			log.Info("pruned episodes", "count", len(removed))
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package pod
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrency is the number of episodes downloaded in parallel
+// when DownloadOptions.Concurrency is left at its zero value.
+const DefaultConcurrency = 4
+
+// partSuffix, etagSuffix and tailSuffix name the on-disk companions of
+// an episode that is still being downloaded. tailSuffix holds the
+// newly fetched bytes of a resumed download until they can be safely
+// combined with the existing ".part" file.
+const (
+	partSuffix = ".part"
+	etagSuffix = ".etag"
+	tailSuffix = ".tail"
+)
+
+// DownloadOptions configures Podcast.DownloadEpisodes.
+type DownloadOptions struct {
+	// Concurrency is the number of episodes downloaded at the same
+	// time. Defaults to DefaultConcurrency if <= 0.
+	Concurrency int
+
+	// RetryMax is the number of retries attempted for an episode
+	// after a transient failure, in addition to the first attempt.
+	RetryMax int
+
+	// RetryBackoff is the delay before the first retry. It doubles
+	// after every subsequent attempt. Defaults to one second if <= 0.
+	RetryBackoff time.Duration
+
+	// Progress, if set, is called periodically while an episode is
+	// downloading. total is -1 if the server did not report a
+	// Content-Length.
+	Progress func(ep *Episode, downloaded, total int64)
+}
+
+// DownloadError describes the failure to download a single episode.
+type DownloadError struct {
+	Episode *Episode
+	Err     error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Episode.Title, e.Err)
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// MultiError collects the DownloadErrors of a batch of episode
+// downloads. It is returned by DownloadEpisodes instead of aborting
+// the batch on the first failure.
+type MultiError []*DownloadError
+
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, e := range m {
+		msgs[i] = e.Error()
+	}
+
+	return fmt.Sprintf("%d episode(s) failed to download:\n%s", len(m), strings.Join(msgs, "\n"))
+}
+
+// DownloadEpisodes retrieves eps and stores them via the podcast's
+// Storage, dispatching downloads across a bounded worker pool. A
+// failure for one episode does not stop the others; once all
+// downloads have finished, a non-nil MultiError is returned if any of
+// them failed. ctx cancellation stops in-flight and not-yet-started
+// downloads; episodes already written to a ".part" file can be
+// resumed on a later call.
+func (pod *Podcast) DownloadEpisodes(ctx context.Context, eps []*Episode, opts DownloadOptions) error {
+	store, err := pod.storage()
+	if err != nil {
+		return err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs MultiError
+
+	for _, e := range eps {
+		e := e
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, &DownloadError{Episode: e, Err: ctx.Err()})
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := downloadWithRetry(ctx, e, store, opts); err != nil {
+				mu.Lock()
+				errs = append(errs, &DownloadError{Episode: e, Err: err})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// downloadWithRetry downloads e, retrying transient failures with
+// exponential backoff.
+func downloadWithRetry(ctx context.Context, e *Episode, store Storage, opts DownloadOptions) error {
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	for attempt := 0; attempt <= opts.RetryMax; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		err = download(ctx, e, store, opts.Progress)
+		if err == nil {
+			return nil
+		}
+
+		if !isRetryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// httpStatusError is returned by download when the server responds
+// with an unexpected status code.
+type httpStatusError struct {
+	code int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.code, http.StatusText(e.code))
+}
+
+// isRetryable reports whether err is worth retrying: network errors
+// and server-side (5xx) HTTP statuses are, client errors (4xx) are not.
+func isRetryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// download retrieves Episode e into store, resuming from a previous
+// ".part" file if one is present. On success the part file is
+// renamed to its final name and e.Bytes is set to the episode's full
+// size, including any bytes resumed from an earlier call.
+func download(ctx context.Context, e *Episode, store Storage, progress func(*Episode, int64, int64)) error {
+	u, err := url.Parse(e.File.URL)
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(u.Path)
+	finalName := e.Title + ext
+	partName := finalName + partSuffix
+	etagName := partName + etagSuffix
+
+	offset, _ := store.Size(partName)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if etag := readETag(store, etagName); etag != "" {
+			req.Header.Set("If-Range", etag)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	resuming := resp.StatusCode == http.StatusPartialContent
+	if resp.StatusCode == http.StatusOK {
+		// The server ignored our Range request (or there was no
+		// partial file to resume), so start over from scratch.
+		offset = 0
+	} else if !resuming {
+		return &httpStatusError{code: resp.StatusCode}
+	}
+
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		writeETag(store, etagName, etag)
+	}
+
+	var total int64 = -1
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	// The newly fetched bytes are written to their own file rather
+	// than straight into partName: when resuming, partName is still
+	// open for reading below, and truncating it in place (as
+	// store.Create would) zeroes out the very bytes we need to
+	// prepend to the response body.
+	writeName := partName
+	if resuming {
+		writeName = partName + tailSuffix
+	}
+
+	f, err := store.Create(writeName)
+	if err != nil {
+		return err
+	}
+
+	pw := &progressWriter{w: f, episode: e, downloaded: offset, total: total, report: progress}
+
+	n, err := io.Copy(pw, resp.Body)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	if resuming {
+		err = assembleResumed(store, partName, writeName, finalName)
+	} else {
+		err = renameInStore(store, partName, finalName)
+	}
+	if err != nil {
+		return err
+	}
+
+	store.Delete(etagName)
+
+	if err := recordDownload(store, finalName, time.Now()); err != nil {
+		return err
+	}
+
+	e.Bytes = offset + n
+
+	return nil
+}
+
+// assembleResumed concatenates the previously downloaded partName
+// with the newly fetched tailName into finalName, then removes both
+// temporary files. Storage has no append, so the head is copied
+// across before the tail.
+func assembleResumed(store Storage, partName, tailName, finalName string) error {
+	head, err := store.Open(partName)
+	if err != nil {
+		return err
+	}
+	defer head.Close()
+
+	dst, err := store.Create(finalName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, head); err != nil {
+		dst.Close()
+		return err
+	}
+
+	tail, err := store.Open(tailName)
+	if err != nil {
+		dst.Close()
+		return err
+	}
+	defer tail.Close()
+
+	if _, err := io.Copy(dst, tail); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	if err := store.Delete(partName); err != nil {
+		return err
+	}
+
+	return store.Delete(tailName)
+}
+
+// progressWriter wraps an io.Writer, reporting cumulative bytes
+// written through report after every write.
+type progressWriter struct {
+	w          io.Writer
+	episode    *Episode
+	downloaded int64
+	total      int64
+	report     func(ep *Episode, downloaded, total int64)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.downloaded += int64(n)
+
+	if p.report != nil {
+		p.report(p.episode, p.downloaded, p.total)
+	}
+
+	return n, err
+}
+
+// renameInStore moves oldName to newName within store. Storage has no
+// native rename, so the content is copied across and the source is
+// removed.
+func renameInStore(store Storage, oldName, newName string) error {
+	src, err := store.Open(oldName)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := store.Create(newName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return store.Delete(oldName)
+}
+
+// readETag returns the ETag previously recorded for a ".part" file in
+// progress, or "" if none is present.
+func readETag(store Storage, etagName string) string {
+	f, err := store.Open(etagName)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(b))
+}
+
+// writeETag records the ETag of an in-progress ".part" file so that a
+// later resume can send it as If-Range. Errors are ignored: losing the
+// ETag only means a resumed download falls back to starting over.
+func writeETag(store Storage, etagName, etag string) {
+	f, err := store.Create(etagName)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	io.WriteString(f, etag)
+}
@@ -0,0 +1,78 @@
+package pod
+
+import (
+	"io"
+	"testing"
+)
+
+func TestAssembleResumed(t *testing.T) {
+	store := NewLocalStorage(t.TempDir())
+
+	writeStoreFile(t, store, "episode.mp3.part", "hello, ")
+	writeStoreFile(t, store, "episode.mp3.part.tail", "world!")
+
+	if err := assembleResumed(store, "episode.mp3.part", "episode.mp3.part.tail", "episode.mp3"); err != nil {
+		t.Fatalf("assembleResumed() = %v", err)
+	}
+
+	if got := readStoreFile(t, store, "episode.mp3"); got != "hello, world!" {
+		t.Fatalf("assembled content = %q, want %q", got, "hello, world!")
+	}
+
+	if _, err := store.Size("episode.mp3.part"); err == nil {
+		t.Fatal("part file still present after assembleResumed")
+	}
+
+	if _, err := store.Size("episode.mp3.part.tail"); err == nil {
+		t.Fatal("tail file still present after assembleResumed")
+	}
+}
+
+func TestRenameInStore(t *testing.T) {
+	store := NewLocalStorage(t.TempDir())
+
+	writeStoreFile(t, store, "episode.mp3.part", "content")
+
+	if err := renameInStore(store, "episode.mp3.part", "episode.mp3"); err != nil {
+		t.Fatalf("renameInStore() = %v", err)
+	}
+
+	if got := readStoreFile(t, store, "episode.mp3"); got != "content" {
+		t.Fatalf("renamed content = %q, want %q", got, "content")
+	}
+
+	if _, err := store.Size("episode.mp3.part"); err == nil {
+		t.Fatal("part file still present after renameInStore")
+	}
+}
+
+func writeStoreFile(t *testing.T, store Storage, name, content string) {
+	t.Helper()
+
+	f, err := store.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := io.WriteString(f, content); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readStoreFile(t *testing.T, store Storage, name string) string {
+	t.Helper()
+
+	f, err := store.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
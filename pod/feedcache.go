@@ -0,0 +1,125 @@
+package pod
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// feedMeta records the validators returned with the last successfully
+// fetched feed, so that a later refresh can make a conditional
+// request instead of re-downloading the whole feed.
+type feedMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// FeedCache stores a podcast's raw feed alongside the HTTP validators
+// needed to refresh it conditionally, avoiding a full re-download of
+// feeds that haven't changed since the last poll.
+type FeedCache struct {
+	store Storage
+}
+
+// NewFeedCache returns a FeedCache backed by store.
+func NewFeedCache(store Storage) *FeedCache {
+	return &FeedCache{store: store}
+}
+
+// Refresh fetches feedURL, sending If-None-Match/If-Modified-Since
+// headers built from the previous refresh. If the server responds
+// 304 Not Modified, the cached feed is left untouched. On 200 OK the
+// response body replaces the cached feed (and any legacy feed.zip is
+// removed) and its validators are saved for the next refresh. Any
+// other status is returned as an error.
+func (c *FeedCache) Refresh(feedURL string) error {
+	meta := c.readMeta()
+
+	req, err := http.NewRequest(http.MethodGet, feedURL, nil)
+	if err != nil {
+		return err
+	}
+
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &httpStatusError{code: resp.StatusCode}
+	}
+
+	f, err := c.store.Create(feedFileName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// The fresh feed supersedes any legacy feed.zip; drop it so that
+	// Open does not migrate the stale archive over the new feed.
+	c.store.Delete(legacyFeedZipName)
+
+	return c.writeMeta(feedMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+}
+
+// Open returns the cached feed for reading. A pre-existing legacy
+// feed.zip is migrated in place first, so that podcasts configured
+// before FeedCache was introduced keep working without ever going
+// through Refresh again.
+func (c *FeedCache) Open() (io.ReadCloser, error) {
+	if err := migrateZipFeed(c.store); err != nil {
+		return nil, err
+	}
+
+	return c.store.Open(feedFileName)
+}
+
+// readMeta returns the previously saved feed validators, or a zero
+// feedMeta if none have been saved yet.
+func (c *FeedCache) readMeta() feedMeta {
+	var meta feedMeta
+
+	f, err := c.store.Open(feedMetaFileName)
+	if err != nil {
+		return meta
+	}
+	defer f.Close()
+
+	json.NewDecoder(f).Decode(&meta)
+
+	return meta
+}
+
+// writeMeta persists meta so the next Refresh can use it.
+func (c *FeedCache) writeMeta(meta feedMeta) error {
+	f, err := c.store.Create(feedMetaFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(meta)
+}
@@ -0,0 +1,82 @@
+package pod
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// downloadManifestFileName records, per downloaded file, the time it
+// finished downloading. Prune uses it to judge the age of an episode
+// that has since fallen out of the live feed, where a pubDate is no
+// longer available.
+const downloadManifestFileName = "downloads.json"
+
+// manifestMu serializes the read-modify-write of downloadManifestFileName.
+// recordDownload runs concurrently out of DownloadEpisodes' worker
+// pool, and the manifest has no way to merge two writers' changes - a
+// plain read, modify, write from each goroutine would let the last
+// write clobber the other's entry.
+var manifestMu sync.Mutex
+
+// recordDownload notes that fileName finished downloading at at.
+func recordDownload(store Storage, fileName string, at time.Time) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := readDownloadManifest(store)
+	if err != nil {
+		return err
+	}
+
+	manifest[fileName] = at
+
+	return writeDownloadManifest(store, manifest)
+}
+
+// forgetDownload removes fileName's entry, once it has been pruned.
+func forgetDownload(store Storage, fileName string) error {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+
+	manifest, err := readDownloadManifest(store)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := manifest[fileName]; !ok {
+		return nil
+	}
+
+	delete(manifest, fileName)
+
+	return writeDownloadManifest(store, manifest)
+}
+
+// readDownloadManifest returns the recorded download times, or an
+// empty manifest if none has been saved yet.
+func readDownloadManifest(store Storage) (map[string]time.Time, error) {
+	manifest := make(map[string]time.Time)
+
+	f, err := store.Open(downloadManifestFileName)
+	if err != nil {
+		return manifest, nil
+	}
+	defer f.Close()
+
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func writeDownloadManifest(store Storage, manifest map[string]time.Time) error {
+	f, err := store.Create(downloadManifestFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(manifest)
+}
@@ -0,0 +1,57 @@
+package pod
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// migrateZipFeed is a one-shot migration that unpacks a pre-existing
+// legacy feed.zip (from before gopodgrab switched to an HTTP-
+// conditional feed cache) into the plain feedFileName entry expected
+// by FeedCache, then removes the archive. It is a no-op if no legacy
+// archive is present.
+func migrateZipFeed(store Storage) error {
+	zf, err := store.Open(legacyFeedZipName)
+	if err != nil {
+		return nil
+	}
+	defer zf.Close()
+
+	b, err := io.ReadAll(zf)
+	if err != nil {
+		return err
+	}
+
+	arc, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return err
+	}
+
+	if len(arc.File) < 1 {
+		return errors.New("pod: legacy feed.zip is empty")
+	}
+
+	rc, err := arc.File[0].Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := store.Create(feedFileName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return store.Delete(legacyFeedZipName)
+}
@@ -1,35 +1,81 @@
 package pod
 
 import (
-	"archive/zip"
-	"io"
-	"net/http"
-	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 )
 
 const (
 	ReservedPodName = "all"
-	feedFileName    = "feed.zip"
+
+	feedFileName     = "feed.xml"
+	feedMetaFileName = "feed.meta.json"
+
+	// legacyFeedZipName is the on-disk name of the zipped feed cache
+	// used before gopodgrab switched to a conditional HTTP cache. It
+	// is only read by migrateZipFeed.
+	legacyFeedZipName = "feed.zip"
 )
 
+// auxiliaryStoreFiles names the entries gopodgrab keeps in a
+// podcast's storage that are not downloaded episodes.
+var auxiliaryStoreFiles = map[string]bool{
+	feedFileName:             true,
+	feedMetaFileName:         true,
+	legacyFeedZipName:        true,
+	downloadManifestFileName: true,
+}
+
+// isAuxiliaryStoreFile reports whether name is one of gopodgrab's own
+// bookkeeping files, or an artifact of a download still in progress,
+// rather than a completed episode.
+func isAuxiliaryStoreFile(name string) bool {
+	if auxiliaryStoreFiles[name] {
+		return true
+	}
+
+	return strings.HasSuffix(name, partSuffix) ||
+		strings.HasSuffix(name, partSuffix+etagSuffix) ||
+		strings.HasSuffix(name, partSuffix+tailSuffix)
+}
+
 // Podcast represents a podcast. It has a feed URL, name
 // and additional metadata.
 type Podcast struct {
-	FeedURL    string `json:"feed_url"`    // URL to retrieve the podcast feed from
-	Name       string `json:"name"`        // The name under which this podcast is managed
-	LocalStore string `json:"local_store"` // Directory path of the local store for this podcast
+	FeedURL    string        `json:"feed_url"`            // URL to retrieve the podcast feed from
+	Name       string        `json:"name"`                // The name under which this podcast is managed
+	LocalStore string        `json:"local_store"`         // Storage URL for this podcast, e.g. "file:///..." or "s3://bucket/prefix"
+	Publish    PublishConfig `json:"publish,omitempty"`   // Metadata used when re-publishing this podcast via `pod serve`
+	Retention  Retention     `json:"retention,omitempty"` // How long downloaded episodes are kept before pruning
+	Schedule   string        `json:"schedule,omitempty"`  // Cron expression used by `pod daemon`; defaults to DefaultSchedule
+
+	store Storage // lazily initialized from LocalStore, see storage()
+}
+
+// storage returns the Storage backing this podcast, constructing it
+// from LocalStore on first use. This makes Podcast safe to populate
+// straight from the JSON configuration file, where store can't be set.
+func (pod *Podcast) storage() (Storage, error) {
+	if pod.store == nil {
+		s, err := NewStorage(pod.LocalStore)
+		if err != nil {
+			return nil, err
+		}
+		pod.store = s
+	}
+
+	return pod.store, nil
 }
 
-// New creates a new podcast and intializes the
-// local storage for it. If creation of the local storage
-// fails, or a podcast by that name is already managed by
-// gopodgrab, an error is returned.
+// New creates a new podcast and intializes its storage. storageURL is
+// a URL-style path selecting the storage backend, e.g. "file:///..."
+// for the local filesystem or "s3://bucket/prefix" to push episodes
+// straight to object storage. If creation of the storage fails, or a
+// podcast by that name is already managed by gopodgrab, an error is
+// returned.
 // If the refresh of the feed, or adding the configuration
 // of the podcast fails, an error is returned, as well.
-func New(name, feedURL, storageDir string) (*Podcast, error) {
+func New(name, feedURL, storageURL string) (*Podcast, error) {
 	if name == ReservedPodName {
 		return nil, ErrReservedName
 	}
@@ -41,10 +87,14 @@ func New(name, feedURL, storageDir string) (*Podcast, error) {
 	pod := &Podcast{
 		Name:       name,
 		FeedURL:    feedURL,
-		LocalStore: storageDir,
+		LocalStore: storageURL,
+	}
+
+	if _, err := pod.storage(); err != nil {
+		return nil, err
 	}
 
-	if err := pod.refreshFeed(); err != nil {
+	if err := pod.RefreshFeed(); err != nil {
 		return nil, err
 	}
 
@@ -89,43 +139,19 @@ func Get(name string) (*Podcast, error) {
 	return pod, nil
 }
 
-// refreshFeed updates the locally stored feed from remote.
-func (pod *Podcast) refreshFeed() error {
-	resp, err := http.Get(pod.FeedURL)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	err = pod.storeExists()
-	if err != nil {
-		return err
-	}
-
-	f, err := os.Create(pod.feedFile())
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	zipper := zip.NewWriter(f)
-
-	file, err := zipper.Create(pod.Name)
-	if err != nil {
-		return err
-	}
-
-	_, err = io.Copy(file, resp.Body)
+// RefreshFeed conditionally updates the locally cached feed from
+// remote, sending the ETag/Last-Modified recorded from the previous
+// refresh so that an unchanged feed costs a single round trip with no
+// body. Callers that poll a podcast on a schedule (pod daemon, pod
+// update) must call this before NewEpisodes, which only ever looks at
+// the cached copy.
+func (pod *Podcast) RefreshFeed() error {
+	store, err := pod.storage()
 	if err != nil {
 		return err
 	}
 
-	err = zipper.Close()
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return NewFeedCache(store).Refresh(pod.FeedURL)
 }
 
 // NewEpisodes reads the feed and compares the list of episodes in
@@ -137,22 +163,15 @@ func (pod *Podcast) NewEpisodes() ([]*Episode, error) {
 		return nil, err
 	}
 
-	arc, err := zip.OpenReader(pod.feedFile())
+	store, err := pod.storage()
 	if err != nil {
 		return nil, err
 	}
 
-	defer arc.Close()
-
-	if len(arc.File) < 1 {
-		return nil, ErrArchiveEmpty
-	}
-
-	feed, err := arc.File[0].Open()
+	feed, err := NewFeedCache(store).Open()
 	if err != nil {
 		return nil, err
 	}
-
 	defer feed.Close()
 
 	feedEpis, err := parseFeed(feed)
@@ -170,98 +189,59 @@ func (pod *Podcast) NewEpisodes() ([]*Episode, error) {
 	return newEpis, nil
 }
 
-// readStore reads the list of episodes that are in the local
-// storage of the podcast returning a set of filenames without
-// extensions.
-func (pod *Podcast) readStore() (map[string]bool, error) {
-	dir, err := os.OpenFile(pod.LocalStore, os.O_RDONLY, os.ModeDir)
+// feedEpisodesByTitle returns every episode currently listed in the
+// podcast's cached feed, keyed by title. It returns a nil map, rather
+// than an error, if the feed can't be read, so that callers built
+// around storage (StoredEpisodes, Prune) keep working once a feed has
+// moved on or become temporarily unreachable.
+func (pod *Podcast) feedEpisodesByTitle() map[string]*Episode {
+	store, err := pod.storage()
 	if err != nil {
-		return nil, err
+		return nil
 	}
 
-	content, err := dir.Readdirnames(0)
+	feed, err := NewFeedCache(store).Open()
 	if err != nil {
-		return nil, err
+		return nil
 	}
+	defer feed.Close()
 
-	stored := make(map[string]bool, len(content))
-
-	for _, e := range content {
-		if e == feedFileName {
-			continue
-		}
-
-		e = strings.TrimSuffix(e, filepath.Ext(e))
-		stored[e] = true
-	}
-
-	return stored, nil
-}
-
-// storeExists ensures that the podcast storage directory is present.
-func (pod *Podcast) storeExists() error {
-	if err := os.MkdirAll(pod.LocalStore, os.ModeDir|0755); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// feedFile returns the full file path of the locally stored, zipped feed.
-func (pod *Podcast) feedFile() string {
-	return filepath.Join(pod.LocalStore, feedFileName)
-}
-
-// dirExists checks whether the directory specified by path exists.
-func dirExists(path string) bool {
-	info, err := os.Stat(path)
+	feedEpis, err := parseFeed(feed)
 	if err != nil {
-		return false
+		return nil
 	}
 
-	return info.IsDir()
-}
-
-// DownloadEpisodes retrieves all episodes and stores them in the local
-// storage. For each retrieved episode the size in bytes is recorded
-// in Episode.Bytes.
-func (pod *Podcast) DownloadEpisodes(eps []*Episode) error {
-	for _, e := range eps {
-
-		if err := download(e, pod.LocalStore); err != nil {
-			return err
-		}
+	byTitle := make(map[string]*Episode, len(feedEpis))
+	for _, e := range feedEpis {
+		byTitle[e.Title] = e
 	}
 
-	return nil
+	return byTitle
 }
 
-// download downloads Episode e to the directory dir.
-func download(e *Episode, dir string) error {
-	u, err := url.Parse(e.File.URL)
+// readStore reads the list of episodes that are in storage, returning
+// a set of filenames without extensions.
+func (pod *Podcast) readStore() (map[string]bool, error) {
+	store, err := pod.storage()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resp, err := http.Get(u.String())
+	content, err := store.List()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	ext := filepath.Ext(u.Path)
+	stored := make(map[string]bool, len(content))
 
-	f, err := os.Create(filepath.Join(dir, e.Title+ext))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	for _, e := range content {
+		if isAuxiliaryStoreFile(e) {
+			continue
+		}
 
-	n, err := io.Copy(f, resp.Body)
-	if err != nil {
-		return err
+		e = strings.TrimSuffix(e, filepath.Ext(e))
+		stored[e] = true
 	}
-	e.Bytes = n
 
-	return nil
+	return stored, nil
 }
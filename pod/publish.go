@@ -0,0 +1,89 @@
+package pod
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// PublishConfig holds the metadata used to re-publish a podcast's
+// archived episodes as a normalized RSS feed via `pod serve`.
+type PublishConfig struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Author      string   `json:"author,omitempty"`
+	ImageURL    string   `json:"image_url,omitempty"`
+	Categories  []string `json:"categories,omitempty"`
+}
+
+// StoredEpisode pairs a feed Episode with the name it was saved under
+// in storage, for re-publishing through pod serve.
+type StoredEpisode struct {
+	Episode  *Episode
+	FileName string
+	Size     int64
+}
+
+// StoredEpisodes returns every episode present in storage, in no
+// particular order, so that pod serve keeps republishing an episode
+// after the upstream feed drops it. Episodes still listed in the feed
+// are paired with their feed metadata; episodes the feed no longer
+// lists fall back to a stand-in Episode built from the stored file
+// name alone.
+func (pod *Podcast) StoredEpisodes() ([]*StoredEpisode, error) {
+	store, err := pod.storage()
+	if err != nil {
+		return nil, err
+	}
+
+	byTitle := pod.feedEpisodesByTitle()
+
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []*StoredEpisode
+	for _, n := range names {
+		if isAuxiliaryStoreFile(n) {
+			continue
+		}
+
+		title := strings.TrimSuffix(n, filepath.Ext(n))
+
+		e, ok := byTitle[title]
+		if !ok {
+			e = &Episode{Title: title}
+		}
+
+		size, err := store.Size(n)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, &StoredEpisode{Episode: e, FileName: n, Size: size})
+	}
+
+	return out, nil
+}
+
+// OpenEpisode opens a file previously returned by StoredEpisodes for
+// reading, along with its size in bytes.
+func (pod *Podcast) OpenEpisode(fileName string) (io.ReadCloser, int64, error) {
+	store, err := pod.storage()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := store.Size(fileName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := store.Open(fileName)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return f, size, nil
+}
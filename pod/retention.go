@@ -0,0 +1,121 @@
+package pod
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Retention configures how long a podcast's archived episodes are
+// kept around.
+type Retention struct {
+	// KeepLatest, if > 0, keeps only the KeepLatest most recent
+	// episodes.
+	KeepLatest int `json:"keep_latest,omitempty"`
+
+	// MaxAge, if > 0, removes episodes older than MaxAge.
+	MaxAge time.Duration `json:"max_age,omitempty"`
+}
+
+// Prune removes stored episodes that exceed the podcast's Retention
+// policy: anything beyond the KeepLatest most recent episodes, and
+// anything older than MaxAge. Episodes are considered even after they
+// have fallen out of the live feed - exactly the long-archived
+// episodes pod serve exists to keep serving - using the feed's
+// pubDate where the episode is still listed, and the time it was
+// downloaded otherwise. If dryRun is true, nothing is deleted and
+// Prune just reports what would be.
+// It returns the filenames that were (or would have been) removed.
+func (pod *Podcast) Prune(dryRun bool) ([]string, error) {
+	if pod.Retention.KeepLatest <= 0 && pod.Retention.MaxAge <= 0 {
+		return nil, nil
+	}
+
+	store, err := pod.storage()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pubDates := pod.feedPubDates()
+
+	downloadedAt, err := readDownloadManifest(store)
+	if err != nil {
+		return nil, err
+	}
+
+	type storedFile struct {
+		name string
+		at   time.Time // zero if no timestamp could be determined
+	}
+
+	var files []storedFile
+	for _, n := range names {
+		if isAuxiliaryStoreFile(n) {
+			continue
+		}
+
+		title := strings.TrimSuffix(n, filepath.Ext(n))
+
+		at, ok := pubDates[title]
+		if !ok {
+			at = downloadedAt[n]
+		}
+
+		files = append(files, storedFile{name: n, at: at})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].at.After(files[j].at)
+	})
+
+	now := time.Now()
+
+	var toRemove []string
+	for i, f := range files {
+		switch {
+		case pod.Retention.KeepLatest > 0 && i >= pod.Retention.KeepLatest:
+			toRemove = append(toRemove, f.name)
+		case pod.Retention.MaxAge > 0 && !f.at.IsZero() && now.Sub(f.at) > pod.Retention.MaxAge:
+			toRemove = append(toRemove, f.name)
+		}
+	}
+
+	removed := make([]string, 0, len(toRemove))
+	for _, name := range toRemove {
+		if !dryRun {
+			if err := store.Delete(name); err != nil {
+				return removed, err
+			}
+
+			if err := forgetDownload(store, name); err != nil {
+				return removed, err
+			}
+		}
+
+		removed = append(removed, name)
+	}
+
+	return removed, nil
+}
+
+// feedPubDates maps episode title to pubDate for every episode
+// currently listed in the podcast's feed. It returns an empty map,
+// rather than an error, if the feed can't be read: Prune should still
+// work from the download manifest for podcasts whose feed has moved
+// on or is temporarily unreachable.
+func (pod *Podcast) feedPubDates() map[string]time.Time {
+	byTitle := pod.feedEpisodesByTitle()
+
+	dates := make(map[string]time.Time, len(byTitle))
+	for title, e := range byTitle {
+		dates[title] = e.PubDate
+	}
+
+	return dates
+}
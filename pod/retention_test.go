@@ -0,0 +1,112 @@
+package pod
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestPodcast(t *testing.T, retention Retention) *Podcast {
+	t.Helper()
+
+	return &Podcast{
+		Name:       "test",
+		LocalStore: t.TempDir(),
+		Retention:  retention,
+	}
+}
+
+// writeAgedFile creates an empty episode file in store and records at
+// as its download time, standing in for an episode whose pubDate is
+// unknown because it has fallen out of the podcast's feed - the feed
+// is never refreshed in these tests, so feedPubDates finds no
+// pubDates and Prune always falls back to the download manifest.
+func writeAgedFile(t *testing.T, store Storage, name string, at time.Time) {
+	t.Helper()
+
+	f, err := store.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if err := recordDownload(store, name, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPruneKeepLatest(t *testing.T) {
+	pod := newTestPodcast(t, Retention{KeepLatest: 2})
+
+	store, err := pod.storage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	writeAgedFile(t, store, "oldest.mp3", now.Add(-3*time.Hour))
+	writeAgedFile(t, store, "middle.mp3", now.Add(-2*time.Hour))
+	writeAgedFile(t, store, "newest.mp3", now.Add(-1*time.Hour))
+
+	removed, err := pod.Prune(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(removed) != 1 || removed[0] != "oldest.mp3" {
+		t.Fatalf("Prune() removed = %v, want [oldest.mp3]", removed)
+	}
+
+	if _, err := store.Size("oldest.mp3"); err == nil {
+		t.Fatal("oldest.mp3 still present in storage after Prune")
+	}
+
+	if _, err := store.Size("newest.mp3"); err != nil {
+		t.Fatalf("newest.mp3 missing after Prune: %v", err)
+	}
+}
+
+func TestPruneMaxAge(t *testing.T) {
+	pod := newTestPodcast(t, Retention{MaxAge: time.Hour})
+
+	store, err := pod.storage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	writeAgedFile(t, store, "stale.mp3", now.Add(-2*time.Hour))
+	writeAgedFile(t, store, "fresh.mp3", now.Add(-10*time.Minute))
+
+	removed, err := pod.Prune(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(removed) != 1 || removed[0] != "stale.mp3" {
+		t.Fatalf("Prune() removed = %v, want [stale.mp3]", removed)
+	}
+}
+
+func TestPruneDryRunLeavesFilesInPlace(t *testing.T) {
+	pod := newTestPodcast(t, Retention{MaxAge: time.Hour})
+
+	store, err := pod.storage()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeAgedFile(t, store, "stale.mp3", time.Now().Add(-2*time.Hour))
+
+	removed, err := pod.Prune(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(removed) != 1 || removed[0] != "stale.mp3" {
+		t.Fatalf("Prune(dryRun) removed = %v, want [stale.mp3]", removed)
+	}
+
+	if _, err := store.Size("stale.mp3"); err != nil {
+		t.Fatalf("dry run deleted stale.mp3: %v", err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package server re-publishes podcasts archived by gopodgrab as a
+// normal HTTP-hosted RSS feed, so that a podcast client can keep
+// consuming a show's locally stored episodes long after the original
+// feed drops them.
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eduncan911/podcast"
+	"github.com/jtepe/gopodgrab/pod"
+)
+
+// enclosureTypesByExt maps a stored episode's file extension to the
+// MIME type reported in its RSS enclosure.
+var enclosureTypesByExt = map[string]podcast.EnclosureType{
+	".mp3":  podcast.MP3,
+	".m4a":  podcast.M4A,
+	".m4v":  podcast.M4V,
+	".mp4":  podcast.MP4,
+	".mov":  podcast.MOV,
+	".pdf":  podcast.PDF,
+	".ogg":  podcast.EnclosureType("audio/ogg"),
+	".opus": podcast.EnclosureType("audio/opus"),
+	".wav":  podcast.EnclosureType("audio/wav"),
+	".flac": podcast.EnclosureType("audio/flac"),
+}
+
+// enclosureType returns the RSS enclosure MIME type for fileName,
+// based on its extension, falling back to a generic binary type for
+// anything unrecognized.
+func enclosureType(fileName string) podcast.EnclosureType {
+	if t, ok := enclosureTypesByExt[strings.ToLower(filepath.Ext(fileName))]; ok {
+		return t
+	}
+
+	return podcast.EnclosureType("application/octet-stream")
+}
+
+// episodeURL builds the URL at which fileName is served for podcast
+// name, escaping both path segments so titles containing spaces,
+// "&", "#", "?" and the like don't produce a broken link.
+func episodeURL(baseURL, name, fileName string) string {
+	return fmt.Sprintf("%s/%s/%s", baseURL, url.PathEscape(name), url.PathEscape(fileName))
+}
+
+// Server serves every managed podcast's feed and episodes over HTTP.
+type Server struct {
+	pods    map[string]*pod.Podcast
+	baseURL string
+}
+
+// New returns a Server publishing pods. baseURL is the externally
+// reachable address of the server (e.g. "http://localhost:8080") and
+// is used to build the enclosure and feed URLs embedded in generated
+// RSS.
+func New(pods []*pod.Podcast, baseURL string) *Server {
+	byName := make(map[string]*pod.Podcast, len(pods))
+	for _, p := range pods {
+		byName[p.Name] = p
+	}
+
+	return &Server{
+		pods:    byName,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Handler returns the http.Handler serving /<podname>/feed.xml and
+// /<podname>/<episode> for every managed podcast.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handle)
+	return mux
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.Trim(r.URL.Path, "/"), "/", 2)
+	if len(parts) == 0 || parts[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	p, ok := s.pods[parts[0]]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 1 || parts[1] == "feed.xml" {
+		s.serveFeed(w, p)
+		return
+	}
+
+	s.serveEpisode(w, r, p, parts[1])
+}
+
+// serveFeed generates an iTunes-compatible RSS feed for p, pointing
+// every enclosure at the locally hosted copy of the episode.
+func (s *Server) serveFeed(w http.ResponseWriter, p *pod.Podcast) {
+	eps, err := p.StoredEpisodes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	title := p.Publish.Title
+	if title == "" {
+		title = p.Name
+	}
+
+	link := fmt.Sprintf("%s/%s/feed.xml", s.baseURL, url.PathEscape(p.Name))
+
+	feed := podcast.New(title, link, p.Publish.Description, nil, nil)
+	feed.AddAuthor(p.Publish.Author, "")
+
+	if p.Publish.ImageURL != "" {
+		feed.AddImage(p.Publish.ImageURL)
+	}
+
+	for _, c := range p.Publish.Categories {
+		feed.AddCategory(c, nil)
+	}
+
+	for _, se := range eps {
+		item := podcast.Item{
+			Title:       se.Episode.Title,
+			Description: se.Episode.Title,
+		}
+
+		item.AddEnclosure(
+			episodeURL(s.baseURL, p.Name, se.FileName),
+			enclosureType(se.FileName),
+			se.Size,
+		)
+
+		if _, err := feed.AddItem(item); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+
+	if err := feed.Encode(w); err != nil {
+		return // headers are already sent, nothing more we can do
+	}
+}
+
+// serveEpisode streams the stored copy of a single episode.
+func (s *Server) serveEpisode(w http.ResponseWriter, r *http.Request, p *pod.Podcast, name string) {
+	f, size, err := p.OpenEpisode(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, name, time.Time{}, rs)
+		return
+	}
+
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", size))
+	io.Copy(w, f)
+}
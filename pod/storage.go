@@ -0,0 +1,114 @@
+package pod
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Storage abstracts the persistence layer used to store and retrieve
+// a podcast's feed and episodes. Implementations may back onto the
+// local filesystem or a remote object store, so that downloaded
+// episodes can be served from wherever the user's media server reads
+// from.
+type Storage interface {
+	// Create opens name for writing, creating it if it does not yet
+	// exist and truncating it if it does.
+	Create(name string) (io.WriteCloser, error)
+
+	// Open opens name for reading.
+	Open(name string) (io.ReadCloser, error)
+
+	// List returns the names of all objects currently held in storage.
+	List() ([]string, error)
+
+	// Delete removes name from storage.
+	Delete(name string) error
+
+	// Size returns the size in bytes of name.
+	Size(name string) (int64, error)
+}
+
+// NewStorage constructs the Storage implementation addressed by
+// storeURL. The scheme of storeURL selects the backend:
+//
+//	(no scheme) or file://path   -> LocalStorage
+//	s3://bucket/prefix           -> S3Storage
+//	minio://endpoint/bucket/prefix -> S3Storage (MinIO-compatible endpoint)
+//
+// An error is returned if storeURL cannot be parsed or names an
+// unsupported scheme.
+func NewStorage(storeURL string) (Storage, error) {
+	u, err := url.Parse(storeURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		path := storeURL
+		if u.Scheme == "file" {
+			path = filepath.Join(u.Host, u.Path)
+		}
+		return NewLocalStorage(path), nil
+	case "s3", "minio":
+		return NewS3Storage(u)
+	default:
+		return nil, fmt.Errorf("pod: unsupported storage scheme %q", u.Scheme)
+	}
+}
+
+// LocalStorage is a Storage implementation backed by a directory on
+// the local filesystem. It preserves gopodgrab's original on-disk
+// layout, one file per object in a single flat directory.
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns a LocalStorage rooted at dir. The directory
+// itself is created lazily, on the first call to Create.
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// ensureExists creates the storage directory if it does not already exist.
+func (s *LocalStorage) ensureExists() error {
+	return os.MkdirAll(s.dir, os.ModeDir|0755)
+}
+
+func (s *LocalStorage) Create(name string) (io.WriteCloser, error) {
+	if err := s.ensureExists(); err != nil {
+		return nil, err
+	}
+
+	return os.Create(filepath.Join(s.dir, name))
+}
+
+func (s *LocalStorage) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, name))
+}
+
+func (s *LocalStorage) List() ([]string, error) {
+	dir, err := os.OpenFile(s.dir, os.O_RDONLY, os.ModeDir)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	return dir.Readdirnames(0)
+}
+
+func (s *LocalStorage) Delete(name string) error {
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+func (s *LocalStorage) Size(name string) (int64, error) {
+	info, err := os.Stat(filepath.Join(s.dir, name))
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
@@ -0,0 +1,71 @@
+package pod
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ErrS3NotImplemented is returned by every S3Storage method until the
+// object-storage backend is wired up to an actual S3/MinIO client.
+var ErrS3NotImplemented = errors.New("pod: S3/MinIO storage is not yet implemented")
+
+// S3Storage is a Storage implementation backed by an S3-compatible
+// object store, so that downloaded episodes can be pushed to object
+// storage for use with remote media servers. It currently only parses
+// its target location; the transfer methods are stubs.
+type S3Storage struct {
+	Endpoint string // non-empty for MinIO or other S3-compatible endpoints
+	Bucket   string
+	Prefix   string
+}
+
+// NewS3Storage builds an S3Storage from a parsed s3:// or minio:// URL.
+//
+//	s3://bucket/prefix
+//	minio://endpoint/bucket/prefix
+func NewS3Storage(u *url.URL) (*S3Storage, error) {
+	s := &S3Storage{}
+
+	switch u.Scheme {
+	case "s3":
+		s.Bucket = u.Host
+		s.Prefix = strings.TrimPrefix(u.Path, "/")
+	case "minio":
+		s.Endpoint = u.Host
+		parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+		s.Bucket = parts[0]
+		if len(parts) == 2 {
+			s.Prefix = parts[1]
+		}
+	default:
+		return nil, errors.New("pod: NewS3Storage called with non-S3 URL")
+	}
+
+	if s.Bucket == "" {
+		return nil, errors.New("pod: S3 storage URL is missing a bucket name")
+	}
+
+	return s, nil
+}
+
+func (s *S3Storage) Create(name string) (io.WriteCloser, error) {
+	return nil, ErrS3NotImplemented
+}
+
+func (s *S3Storage) Open(name string) (io.ReadCloser, error) {
+	return nil, ErrS3NotImplemented
+}
+
+func (s *S3Storage) List() ([]string, error) {
+	return nil, ErrS3NotImplemented
+}
+
+func (s *S3Storage) Delete(name string) error {
+	return ErrS3NotImplemented
+}
+
+func (s *S3Storage) Size(name string) (int64, error) {
+	return 0, ErrS3NotImplemented
+}